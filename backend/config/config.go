@@ -15,39 +15,46 @@ import (
 	"github.com/joho/godotenv"
 )
 
+// Config is plain data: loading it is the only place that touches the
+// environment. Field json tags name the paths accepted by the admin
+// PATCH /api/v1/admin/config endpoint (see ApplyPatch); Key and Path are
+// tagged "-" so secrets can never be read back or hot-patched over HTTP.
 type Config struct {
-	Port     string
-	Database DatabaseConfig
-	API      APIConfig
-	Server   ServerConfig
+	Port     string         `json:"port"`
+	Database DatabaseConfig `json:"database"`
+	API      APIConfig      `json:"api"`
+	Server   ServerConfig   `json:"server"`
 }
 
 type DatabaseConfig struct {
-	Path         string
-	WALMode      bool
-	TimeoutSecs  int
-	MaxOpenConns int
-	MaxIdleConns int
+	Driver       string `json:"driver"`
+	Path         string `json:"-"`
+	WALMode      bool   `json:"walMode"`
+	TimeoutSecs  int    `json:"timeoutSecs"`
+	MaxOpenConns int    `json:"maxOpenConns"`
+	MaxIdleConns int    `json:"maxIdleConns"`
 }
 
 type APIConfig struct {
-	Key string
+	Key string `json:"-"`
 }
 
 type RateLimit struct {
-	PerIPLimit    int
-	ClearInterval time.Duration
+	Backend       string        `json:"backend"` // "memory" or "redis"
+	PerIPLimit    int           `json:"perIPLimit"`
+	ClearInterval time.Duration `json:"clearInterval"`
+	RedisURL      string        `json:"redisURL"`
 }
 
 type ServerConfig struct {
-	AllowedOrigins   []string
-	ConnectionsLimit int
-	RateLimit        RateLimit
-	RequestSizeLimit int64
-	ReadTimeout      time.Duration
-	WriteTimeout     time.Duration
-	IdleTimeout      time.Duration
-	HandlerTimeout   time.Duration
+	AllowedOrigins   []string      `json:"allowedOrigins"`
+	ConnectionsLimit int           `json:"connectionsLimit"`
+	RateLimit        RateLimit     `json:"rateLimit"`
+	RequestSizeLimit int64         `json:"requestSizeLimit"`
+	ReadTimeout      time.Duration `json:"readTimeout"`
+	WriteTimeout     time.Duration `json:"writeTimeout"`
+	IdleTimeout      time.Duration `json:"idleTimeout"`
+	HandlerTimeout   time.Duration `json:"handlerTimeout"`
 }
 
 func Load() (*Config, error) {
@@ -58,6 +65,7 @@ func Load() (*Config, error) {
 	config := &Config{
 		Port: getEnv("PORT", "8080"),
 		Database: DatabaseConfig{
+			Driver:       getEnv("DB_DRIVER", "sqlite"),
 			Path:         utils.Must(getEnvWithoutDefault("DB_PATH")),
 			WALMode:      getBoolEnv("DB_WAL_MODE", true),
 			TimeoutSecs:  getIntEnv("DB_TIMEOUT", 30),
@@ -71,8 +79,10 @@ func Load() (*Config, error) {
 			AllowedOrigins:   getSliceEnv("ALLOWED_ORIGINS", []string{}),
 			ConnectionsLimit: getIntEnv("CONNECTIONS_LIMIT", 100),
 			RateLimit: RateLimit{
+				Backend:       getEnv("RATE_LIMIT_BACKEND", "memory"),
 				PerIPLimit:    getIntEnv("RATE_LIMIT", 10),
 				ClearInterval: time.Duration(getIntEnv("RATE_LIMIT_CLEAR_INTERVAL_SECS", 60)) * time.Second,
+				RedisURL:      getEnv("REDIS_URL", ""),
 			},
 			RequestSizeLimit: 1024 * 10, // 10 KB
 			ReadTimeout:      10 * time.Second,
@@ -0,0 +1,246 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/joho/godotenv"
+)
+
+// envPath is the .env file watched for hot reloads.
+const envPath = ".env"
+
+// ConfigHandler owns the live Config and supports safe hot reloads: reads
+// are lock-free via an atomic.Pointer, while writers (the .env file
+// watcher and DoLockedAction's guarded admin updates) serialize through
+// mu so a load-check-publish sequence can't race another one, and
+// observers can't be read by publish while OnChange is appending to it.
+// Handlers and the router should hold a *ConfigHandler and call Get() on
+// every request rather than capturing a *Config at startup, so they pick
+// up reloads immediately.
+type ConfigHandler struct {
+	mu        sync.Mutex
+	current   atomic.Pointer[Config]
+	watcher   *fsnotify.Watcher
+	observers []func(*Config)
+}
+
+// NewHandler loads the initial config and starts watching .env for
+// changes.
+func NewHandler() (*ConfigHandler, error) {
+	cfg, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	h := &ConfigHandler{}
+	h.current.Store(cfg)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("create config watcher: %w", err)
+	}
+
+	// Watch the containing directory rather than envPath itself: editors
+	// and deploy tooling commonly save via write-temp-then-rename, which
+	// replaces the watched inode and would silently stop delivering
+	// events if we watched the file directly.
+	watchDir := filepath.Dir(envPath)
+	if err := watcher.Add(watchDir); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("watch %s: %w", watchDir, err)
+	}
+	h.watcher = watcher
+
+	go h.watchLoop()
+
+	return h, nil
+}
+
+// Close stops the .env file watcher.
+func (h *ConfigHandler) Close() error {
+	return h.watcher.Close()
+}
+
+// Get returns the currently active config. Treat the returned pointer as
+// read-only; mutate via DoLockedAction instead.
+func (h *ConfigHandler) Get() *Config {
+	return h.current.Load()
+}
+
+// OnChange registers a callback invoked, in registration order, every
+// time a new config is published (by the .env watcher or by
+// DoLockedAction). Used e.g. to reapply database pool sizes with
+// SetMaxOpenConns/SetMaxIdleConns without restarting the process.
+func (h *ConfigHandler) OnChange(observer func(*Config)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.observers = append(h.observers, observer)
+}
+
+// Fingerprint returns a stable hash of the current config. Callers of
+// DoLockedAction must pass back the fingerprint they last observed so
+// concurrent updates are rejected instead of silently lost.
+func (h *ConfigHandler) Fingerprint() string {
+	return fingerprint(h.current.Load())
+}
+
+func fingerprint(cfg *Config) string {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		log.Fatalf("marshal config for fingerprint: %v", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ErrStaleFingerprint is returned by DoLockedAction when expectedFingerprint
+// no longer matches the live config, i.e. someone else updated it first.
+var ErrStaleFingerprint = errors.New("config fingerprint is stale")
+
+// DoLockedAction applies cb to a copy of the live config if and only if
+// expectedFingerprint still matches, then atomically publishes the
+// result. It returns ErrStaleFingerprint on a mismatch so callers (e.g.
+// the admin PATCH endpoint) can respond 409 Conflict instead of
+// clobbering a concurrent update. The whole load-check-publish sequence
+// runs under mu so two concurrent calls that both observe the same
+// fingerprint can't both pass the check: the second sees the first's
+// published config and is rejected instead of silently overwriting it.
+func (h *ConfigHandler) DoLockedAction(expectedFingerprint string, cb func(*Config) error) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	current := h.current.Load()
+	if fingerprint(current) != expectedFingerprint {
+		return ErrStaleFingerprint
+	}
+
+	next := *current
+	// Copy slice fields too: ApplyPatch decodes into them in place, which
+	// would otherwise write through to the live config's backing array.
+	next.Server.AllowedOrigins = append(current.Server.AllowedOrigins[:0:0], current.Server.AllowedOrigins...)
+	if err := cb(&next); err != nil {
+		return err
+	}
+
+	if err := next.validate(); err != nil {
+		return err
+	}
+
+	h.publishLocked(&next)
+	return nil
+}
+
+// publish acquires mu before publishing, for callers (the .env watcher)
+// that aren't already holding it.
+func (h *ConfigHandler) publish(cfg *Config) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.publishLocked(cfg)
+}
+
+// publishLocked stores cfg and notifies observers. Callers must hold mu.
+func (h *ConfigHandler) publishLocked(cfg *Config) {
+	h.current.Store(cfg)
+	for _, observer := range h.observers {
+		observer(cfg)
+	}
+}
+
+func (h *ConfigHandler) watchLoop() {
+	for {
+		select {
+		case event, ok := <-h.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Base(event.Name) != envPath {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+
+			// Load's godotenv.Load never overrides variables that are
+			// already set, and the first Load set them all, so Overload is
+			// what makes edits to .env take effect.
+			if err := godotenv.Overload(); err != nil {
+				log.Printf("config reload from .env failed, keeping previous config: %v", err)
+				continue
+			}
+
+			cfg, err := Load()
+			if err != nil {
+				log.Printf("config reload from .env failed, keeping previous config: %v", err)
+				continue
+			}
+
+			h.publish(cfg)
+			log.Printf("config reloaded from .env (fingerprint %s)", fingerprint(cfg))
+		case err, ok := <-h.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("config watcher error: %v", err)
+		}
+	}
+}
+
+// ApplyPatch applies a JSON-Path style partial update to cfg, where each
+// key in patch is a dotted path of field json tags (e.g.
+// "server.rateLimit.perIPLimit") and the value is the replacement encoded
+// as JSON. Unknown paths are rejected so a typo in an admin request fails
+// loudly instead of silently no-opping.
+func ApplyPatch(cfg *Config, patch map[string]json.RawMessage) error {
+	for path, raw := range patch {
+		if err := setPath(reflect.ValueOf(cfg).Elem(), strings.Split(path, "."), raw); err != nil {
+			return fmt.Errorf("apply patch %q: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+func setPath(v reflect.Value, segments []string, raw json.RawMessage) error {
+	field, err := fieldByJSONName(v, segments[0])
+	if err != nil {
+		return err
+	}
+
+	if len(segments) == 1 {
+		return json.Unmarshal(raw, field.Addr().Interface())
+	}
+
+	if field.Kind() != reflect.Struct {
+		return fmt.Errorf("%q is not an object", segments[0])
+	}
+
+	return setPath(field, segments[1:], raw)
+}
+
+func fieldByJSONName(v reflect.Value, name string) (reflect.Value, error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if jsonName == "" || jsonName == "-" {
+			continue
+		}
+		if strings.EqualFold(jsonName, name) {
+			return v.Field(i), nil
+		}
+	}
+
+	return reflect.Value{}, fmt.Errorf("unknown field %q", name)
+}
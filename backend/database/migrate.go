@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// Migration is a single numbered schema change, loaded from a pair of
+// `NNNN_name.up.sql` / `NNNN_name.down.sql` files. Down is loaded and kept
+// alongside Up for when a rollback runner lands, but nothing applies it
+// yet: MigrationRunner.Apply is forward-only.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+var migrationFileRE = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// LoadMigrations reads every `NNNN_name.{up,down}.sql` file in dir (an
+// embedded migrations directory) and returns them sorted by version.
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("read migrations dir: %w", err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		matches := migrationFileRE.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+
+		version, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return nil, fmt.Errorf("parse migration version in %s: %w", entry.Name(), err)
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = m
+		}
+
+		if matches[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// MigrationRunner applies migrations to a *sql.DB, tracking applied
+// versions in a schema_migrations table. CreateTrackingTable and
+// InsertVersion are dialect-specific SQL supplied by the driver, since
+// table-creation syntax and bind-parameter placeholders differ across
+// sqlite/postgres/mysql.
+type MigrationRunner struct {
+	DB                  *sql.DB
+	CreateTrackingTable string
+	InsertVersion       string
+}
+
+// Apply runs every migration not yet recorded in schema_migrations, each
+// inside its own transaction: the up script and the version record are
+// committed together or not at all. On MySQL, DDL statements commit
+// implicitly, so there a failed up script can leave its earlier statements
+// applied without a version record. There is no rollback path yet — it
+// only ever runs Migration.Up; Down is loaded by LoadMigrations but
+// unused here until a rollback runner is added.
+func (r MigrationRunner) Apply(ctx context.Context, migrations []Migration) error {
+	if _, err := r.DB.ExecContext(ctx, r.CreateTrackingTable); err != nil {
+		return fmt.Errorf("create schema_migrations table: %w", err)
+	}
+
+	applied, err := r.appliedVersions(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+
+		if err := r.applyOne(ctx, m); err != nil {
+			return fmt.Errorf("apply migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (r MigrationRunner) appliedVersions(ctx context.Context) (map[int]bool, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("list applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := map[int]bool{}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("scan applied migration: %w", err)
+		}
+		applied[version] = true
+	}
+
+	return applied, rows.Err()
+}
+
+func (r MigrationRunner) applyOne(ctx context.Context, m Migration) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, r.InsertVersion, m.Version, m.Name); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,209 @@
+/*
+Package sqlite is the sqlite3 implementation of database.Store.
+*/
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"backend/config"
+	"backend/database"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+type Store struct {
+	db *sql.DB
+}
+
+func New(cfg *config.Config) (*Store, error) {
+	db, err := sql.Open("sqlite3", cfg.Database.Path)
+	if err != nil {
+		log.Printf("Database connection failed: %v", err)
+		return nil, errors.New("database connection failed")
+	}
+
+	timeoutMS := cfg.Database.TimeoutSecs * 1000
+	pragmas := []string{
+		"PRAGMA foreign_keys=ON;",
+		fmt.Sprintf("PRAGMA busy_timeout=%d;", timeoutMS),
+	}
+
+	if cfg.Database.WALMode {
+		pragmas = append(pragmas,
+			"PRAGMA journal_mode=WAL;",
+			"PRAGMA synchronous=NORMAL;",
+			"PRAGMA cache_size=1000;",   // 1000 pages, ~4MB at the default 4KB page size
+			"PRAGMA temp_store=memory;", // Use memory for temp tables
+		)
+	}
+
+	for _, pragma := range pragmas {
+		if _, err := db.Exec(pragma); err != nil {
+			log.Printf("Failed to set PRAGMA settings in SQLite: %s\nFull error: %v", pragma, err)
+			return nil, errors.New("failed to set SQLite PRAGMA settings")
+		}
+	}
+
+	db.SetConnMaxLifetime(time.Duration(cfg.Database.TimeoutSecs) * time.Second)
+	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+
+	if err := db.Ping(); err != nil {
+		log.Printf("Failed to ping database: %v", err)
+		return nil, errors.New("failed to ping database")
+	}
+
+	store := &Store{db: db}
+	if err := store.migrate(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (s *Store) migrate() error {
+	migrations, err := database.LoadMigrations(migrationsFS, "migrations")
+	if err != nil {
+		return fmt.Errorf("load sqlite migrations: %w", err)
+	}
+
+	runner := database.MigrationRunner{
+		DB: s.db,
+		CreateTrackingTable: `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);`,
+		InsertVersion: "INSERT INTO schema_migrations (version, name) VALUES (?, ?)",
+	}
+
+	if err := runner.Apply(context.Background(), migrations); err != nil {
+		return fmt.Errorf("apply sqlite migrations: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) Reconfigure(maxOpenConns, maxIdleConns int) {
+	s.db.SetMaxOpenConns(maxOpenConns)
+	s.db.SetMaxIdleConns(maxIdleConns)
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) ListPosts(ctx context.Context) ([]database.BlogPost, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, content, tags, published_at, updated_at FROM blog_posts ORDER BY published_at DESC")
+	if err != nil {
+		return nil, fmt.Errorf("list blog posts: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []database.BlogPost
+	for rows.Next() {
+		var post database.BlogPost
+		if err := rows.Scan(&post.ID, &post.Content, &post.Tags, &post.PublishedAt, &post.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan blog post: %w", err)
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+func (s *Store) GetPost(ctx context.Context, id int64) (database.BlogPost, error) {
+	var post database.BlogPost
+	row := s.db.QueryRowContext(ctx,
+		"SELECT id, content, tags, published_at, updated_at FROM blog_posts WHERE id = ?", id)
+	if err := row.Scan(&post.ID, &post.Content, &post.Tags, &post.PublishedAt, &post.UpdatedAt); err != nil {
+		return database.BlogPost{}, fmt.Errorf("get blog post %d: %w", id, err)
+	}
+
+	return post, nil
+}
+
+func (s *Store) CreatePost(ctx context.Context, post database.BlogPost) (database.BlogPost, error) {
+	result, err := s.db.ExecContext(ctx,
+		"INSERT INTO blog_posts (content, tags, published_at, updated_at) VALUES (?, ?, ?, ?)",
+		post.Content, post.Tags, post.PublishedAt, post.UpdatedAt)
+	if err != nil {
+		return database.BlogPost{}, fmt.Errorf("create blog post: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return database.BlogPost{}, fmt.Errorf("create blog post: %w", err)
+	}
+
+	post.ID = id
+	return post, nil
+}
+
+func (s *Store) UpdatePost(ctx context.Context, post database.BlogPost) error {
+	_, err := s.db.ExecContext(ctx,
+		"UPDATE blog_posts SET content = ?, tags = ?, published_at = ?, updated_at = ? WHERE id = ?",
+		post.Content, post.Tags, post.PublishedAt, post.UpdatedAt, post.ID)
+	if err != nil {
+		return fmt.Errorf("update blog post %d: %w", post.ID, err)
+	}
+
+	return nil
+}
+
+func (s *Store) DeletePost(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM blog_posts WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("delete blog post %d: %w", id, err)
+	}
+
+	return nil
+}
+
+func (s *Store) CreateMessage(ctx context.Context, msg database.ContactMessage) (database.ContactMessage, error) {
+	result, err := s.db.ExecContext(ctx,
+		"INSERT INTO contact_messages (name, email, message, created_at) VALUES (?, ?, ?, ?)",
+		msg.Name, msg.Email, msg.Message, msg.CreatedAt)
+	if err != nil {
+		return database.ContactMessage{}, fmt.Errorf("create contact message: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return database.ContactMessage{}, fmt.Errorf("create contact message: %w", err)
+	}
+
+	msg.ID = id
+	return msg, nil
+}
+
+func (s *Store) ListMessages(ctx context.Context) ([]database.ContactMessage, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, name, email, message, created_at FROM contact_messages ORDER BY created_at DESC")
+	if err != nil {
+		return nil, fmt.Errorf("list contact messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []database.ContactMessage
+	for rows.Next() {
+		var msg database.ContactMessage
+		if err := rows.Scan(&msg.ID, &msg.Name, &msg.Email, &msg.Message, &msg.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan contact message: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+
+	return messages, rows.Err()
+}
@@ -0,0 +1,57 @@
+/*
+Package database defines the storage contract the rest of the module
+depends on. Concrete drivers live in the sqlite, postgres and mysql
+subpackages and are selected at startup by config.DatabaseConfig.Driver;
+handlers and other packages should only ever hold a Store, never a
+*sql.DB.
+*/
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// BlogPost mirrors a row in the blog_posts table.
+type BlogPost struct {
+	ID          int64
+	Content     string
+	Tags        string
+	PublishedAt time.Time
+	UpdatedAt   time.Time
+}
+
+// BlogStore is the persistence contract for blog posts.
+type BlogStore interface {
+	ListPosts(ctx context.Context) ([]BlogPost, error)
+	GetPost(ctx context.Context, id int64) (BlogPost, error)
+	CreatePost(ctx context.Context, post BlogPost) (BlogPost, error)
+	UpdatePost(ctx context.Context, post BlogPost) error
+	DeletePost(ctx context.Context, id int64) error
+}
+
+// ContactMessage mirrors a row in the contact_messages table.
+type ContactMessage struct {
+	ID        int64
+	Name      string
+	Email     string
+	Message   string
+	CreatedAt time.Time
+}
+
+// ContactStore is the persistence contract for inbound contact messages.
+type ContactStore interface {
+	CreateMessage(ctx context.Context, msg ContactMessage) (ContactMessage, error)
+	ListMessages(ctx context.Context) ([]ContactMessage, error)
+}
+
+// Store is the full storage contract implemented by each driver
+// subpackage (sqlite, postgres, mysql).
+type Store interface {
+	BlogStore
+	ContactStore
+	// Reconfigure reapplies connection pool sizing, so a config hot
+	// reload can change it without reopening the database.
+	Reconfigure(maxOpenConns, maxIdleConns int)
+	Close() error
+}
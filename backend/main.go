@@ -15,19 +15,42 @@ import (
 	"backend/config"
 	"backend/contact"
 	"backend/database"
+	"backend/database/mysql"
+	"backend/database/postgres"
+	"backend/database/sqlite"
 	"backend/router"
 	"backend/utils"
 )
 
+func newStore(cfg *config.Config) (database.Store, error) {
+	switch cfg.Database.Driver {
+	case "postgres":
+		return postgres.New(cfg)
+	case "mysql":
+		return mysql.New(cfg)
+	case "sqlite", "":
+		return sqlite.New(cfg)
+	default:
+		return nil, fmt.Errorf("unknown DB_DRIVER %q", cfg.Database.Driver)
+	}
+}
+
 func main() {
-	cfg := utils.Must(config.Load())
-	db := utils.Must(database.NewSQLiteDB(cfg))
-	r := router.New(cfg)
+	cfgHandler := utils.Must(config.NewHandler())
+	defer cfgHandler.Close()
+
+	cfg := cfgHandler.Get()
+	db := utils.Must(newStore(cfg))
+	cfgHandler.OnChange(func(next *config.Config) {
+		db.Reconfigure(next.Database.MaxOpenConns, next.Database.MaxIdleConns)
+	})
+
+	r := router.New(cfgHandler)
 
 	r.SetupRoutes(&router.RoutingContext{
 		Providers: []router.RouteProvider{
 		},
-	}, cfg)
+	}, cfgHandler)
 
 	port := ":" + cfg.Port
 	var buf bytes.Buffer
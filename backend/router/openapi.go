@@ -0,0 +1,225 @@
+package router
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteOpenAPI walks the route tree and emits an OpenAPI 3.1 document
+// describing every registered route, mirroring WriteRoutes. Request/response
+// types attached via RouteNode.WithRequestBody/WithResponse are reflected
+// into components.schemas, and routes built with RouteNode.WithAuth are
+// marked as requiring the bearerAuth security scheme backed by
+// utils.MiddlewareAPIAuth.
+func (r *routerWrapped) WriteOpenAPI(writer io.Writer) error {
+	schemas := map[string]any{}
+	paths := map[string]any{}
+
+	root := (*r.routeTree)[0]
+	r.collectOpenAPIPaths(root, "", paths, schemas)
+
+	doc := map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":   "stund API",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{
+					"type":   "http",
+					"scheme": "bearer",
+				},
+			},
+		},
+	}
+
+	enc := json.NewEncoder(writer)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+func (r *routerWrapped) collectOpenAPIPaths(node *RouteNode, basePath string, paths, schemas map[string]any) {
+	currentPath := basePath + node.info.route
+
+	if node.handler != nil && node.info.method != Undefined {
+		operation := map[string]any{
+			"summary":   node.meta.Summary,
+			"tags":      node.meta.Tags,
+			"responses": openAPIResponses(node.meta.Responses, schemas),
+		}
+
+		if node.meta.RequiresAuth {
+			operation["security"] = []any{map[string]any{"bearerAuth": []string{}}}
+		}
+
+		if node.meta.RequestBody != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"application/json": map[string]any{
+						"schema": reflectSchema(reflect.TypeOf(node.meta.RequestBody), schemas),
+					},
+				},
+			}
+		}
+
+		pathItem, ok := paths[currentPath].(map[string]any)
+		if !ok {
+			pathItem = map[string]any{}
+			paths[currentPath] = pathItem
+		}
+		pathItem[strings.ToLower(string(node.info.method))] = operation
+	}
+
+	for _, child := range node.children {
+		r.collectOpenAPIPaths(child, currentPath, paths, schemas)
+	}
+}
+
+func openAPIResponses(responses map[int]any, schemas map[string]any) map[string]any {
+	result := map[string]any{}
+	for status, body := range responses {
+		result[strconv.Itoa(status)] = map[string]any{
+			"description": http.StatusText(status),
+			"content": map[string]any{
+				"application/json": map[string]any{
+					"schema": envelopeSchema(body, schemas),
+				},
+			},
+		}
+	}
+
+	if len(result) == 0 {
+		result["200"] = map[string]any{"description": http.StatusText(http.StatusOK)}
+	}
+
+	return result
+}
+
+// envelopeSchema describes a response wrapped in utils.APIResponse, with
+// Data narrowed to the schema of body.
+func envelopeSchema(body any, schemas map[string]any) map[string]any {
+	properties := map[string]any{
+		"success": map[string]any{"type": "boolean"},
+		"error":   map[string]any{"type": "string"},
+	}
+
+	if body != nil {
+		properties["data"] = reflectSchema(reflect.TypeOf(body), schemas)
+	}
+
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// reflectSchema builds a JSON Schema fragment for t, registering named
+// structs into schemas and returning a $ref rather than inlining them.
+func reflectSchema(t reflect.Type, schemas map[string]any) map[string]any {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct:
+		name := schemaName(t)
+		if name == "" {
+			return structSchema(t, schemas)
+		}
+		if _, ok := schemas[name]; !ok {
+			// Register a placeholder before recursing into structSchema so a
+			// self-referential (or mutually recursive) struct finds its own
+			// name already present and returns a $ref instead of looping
+			// forever; structSchema's result overwrites it once it returns.
+			schemas[name] = map[string]any{}
+			schemas[name] = structSchema(t, schemas)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return map[string]any{"type": "array", "items": reflectSchema(t.Elem(), schemas)}
+	case t.Kind() == reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": reflectSchema(t.Elem(), schemas)}
+	case t.Kind() == reflect.String:
+		return map[string]any{"type": "string"}
+	case t.Kind() == reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case isIntKind(t.Kind()):
+		return map[string]any{"type": "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{}
+	}
+}
+
+func structSchema(t reflect.Type, schemas map[string]any) map[string]any {
+	properties := map[string]any{}
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = reflectSchema(field.Type, schemas)
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// schemaName qualifies t's name with its package so that same-named
+// structs from different packages (e.g. blog.Post vs contact.Post) don't
+// collide in components.schemas. Anonymous types return "" and are
+// inlined by the caller instead of registered.
+func schemaName(t reflect.Type) string {
+	if t.Name() == "" {
+		return ""
+	}
+	if pkg := path.Base(t.PkgPath()); pkg != "" && pkg != "." {
+		return pkg + "." + t.Name()
+	}
+	return t.Name()
+}
+
+func isIntKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
@@ -4,16 +4,19 @@ Package router ...
 package router
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
-	"github.com/go-chi/httprate"
 
 	"backend/config"
+	"backend/metrics"
 	"backend/utils"
 )
 
@@ -23,6 +26,7 @@ const (
 	GET       HTTPMethod = "GET"
 	POST      HTTPMethod = "POST"
 	PUT       HTTPMethod = "PUT"
+	PATCH     HTTPMethod = "PATCH"
 	DELETE    HTTPMethod = "DELETE"
 	Undefined HTTPMethod = "Undefined"
 )
@@ -37,6 +41,19 @@ type RouteNode struct {
 	info        requestInfo
 	middlewares []func(http.Handler) http.Handler
 	handler     func(w http.ResponseWriter, r *http.Request)
+	meta        RouteMeta
+}
+
+// RouteMeta carries the optional documentation metadata used by
+// WriteOpenAPI to describe a route. A zero-value RouteMeta is valid; the
+// route is simply documented with no request body, no typed responses and
+// no tags.
+type RouteMeta struct {
+	Summary      string
+	Tags         []string
+	RequestBody  any
+	Responses    map[int]any
+	RequiresAuth bool
 }
 
 func (n *RouteNode) newRouteNode(method HTTPMethod, pattern string, handler func(w http.ResponseWriter, r *http.Request)) *RouteNode {
@@ -59,27 +76,52 @@ type routerWrapped struct {
 }
 
 type RouteProvider interface {
-	RegisterRoutes(*RouteNode, *config.Config)
+	RegisterRoutes(*RouteNode, *config.ConfigHandler)
 }
 
 type RoutingContext struct {
 	Providers []RouteProvider
 }
 
-func New(cfg *config.Config) *routerWrapped {
+// New builds the chi mux and its middleware stack. cfgHandler is read
+// once here for settings chi can only configure at construction time
+// (request size limit, connection throttle); Timeout and CORS instead
+// re-read cfgHandler on every request so they pick up a hot reload without
+// a restart. Rate limiting re-reads it per request only on the redis
+// backend; the in-memory limiter keeps the limit and window read here
+// (see utils.MiddlewareRateLimit).
+func New(cfgHandler *config.ConfigHandler) *routerWrapped {
+	cfg := cfgHandler.Get()
+
 	chiRouter := chi.NewRouter()
 	chiRouter.Use(
-		middleware.Logger,
 		middleware.Recoverer,
 		middleware.RequestID,
 		middleware.RealIP,
+		utils.MiddlewareRequestLogger,
+		metrics.Middleware,
 		middleware.RequestSize(cfg.Server.RequestSizeLimit),
-		middleware.Timeout(cfg.Server.HandlerTimeout),
+		dynamicTimeout(cfgHandler),
 		middleware.Throttle(cfg.Server.ConnectionsLimit),
-		httprate.LimitByRealIP(cfg.Server.RateLimit.PerIPLimit, cfg.Server.RateLimit.ClearInterval),
+		utils.MiddlewareRateLimit(func() utils.RateLimitConfig {
+			rateLimit := cfgHandler.Get().Server.RateLimit
+			return utils.RateLimitConfig{
+				Backend:       rateLimit.Backend,
+				RedisURL:      rateLimit.RedisURL,
+				Limit:         rateLimit.PerIPLimit,
+				ClearInterval: rateLimit.ClearInterval,
+			}
+		}),
 		cors.Handler(cors.Options{
-			AllowedOrigins:   cfg.Server.AllowedOrigins,
-			AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE"},
+			AllowOriginFunc: func(r *http.Request, origin string) bool {
+				for _, allowed := range cfgHandler.Get().Server.AllowedOrigins {
+					if allowed == origin {
+						return true
+					}
+				}
+				return false
+			},
+			AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
 			AllowedHeaders:   []string{"Content-Type", "Authorization"},
 			AllowCredentials: false,
 			MaxAge:           300,
@@ -101,27 +143,84 @@ func New(cfg *config.Config) *routerWrapped {
 	}
 }
 
+// dynamicTimeout re-reads cfgHandler on every request so HandlerTimeout
+// changes made via the admin config endpoint apply immediately.
+func dynamicTimeout(cfgHandler *config.ConfigHandler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout := cfgHandler.Get().Server.HandlerTimeout
+			middleware.Timeout(timeout)(next).ServeHTTP(w, r)
+		})
+	}
+}
+
 func (r *routerWrapped) GetHTTPHandler() *chi.Mux {
 	return r.internal
 }
 
-func (r *routerWrapped) SetupRoutes(context *RoutingContext, cfg *config.Config) {
+func (r *routerWrapped) SetupRoutes(context *RoutingContext, cfgHandler *config.ConfigHandler) {
 	const version = "v1"
 	const apiRoot = "/api" + "/" + version
 	root := (*r.routeTree)[0]
 	root.Route(apiRoot, func(n *RouteNode) {
 		for _, providers := range context.Providers {
-			providers.RegisterRoutes(n, cfg)
+			providers.RegisterRoutes(n, cfgHandler)
 		}
+
+		n.Route("/admin", func(admin *RouteNode) {
+			admin.Patch("/config", adminPatchConfigHandler(cfgHandler)).
+				With(utils.MiddlewareAPIAuth(cfgHandler.Get().API.Key)).
+				WithAuth().
+				WithSummary("Hot-patch the live server config").
+				WithTags("admin")
+		})
 	})
 
 	root.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		utils.RespondWithJSON(w, http.StatusOK, true, map[string]string{"status": "healthy"}, "")
 	})
 
+	root.Get("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		metrics.Handler().ServeHTTP(w, r)
+	}).With(utils.MiddlewareAPIAuth(cfgHandler.Get().API.Key))
+
 	r.registerWithChi(root, "")
 }
 
+// adminPatchConfigRequest is the body accepted by PATCH /api/v1/admin/config.
+type adminPatchConfigRequest struct {
+	Fingerprint string                     `json:"fingerprint"`
+	Patch       map[string]json.RawMessage `json:"patch"`
+}
+
+// adminPatchConfigHandler applies a CAS-guarded partial update to the
+// live config: the caller must supply the Fingerprint it last observed
+// (from this endpoint's response or ConfigHandler.Fingerprint), or the
+// update is rejected with 409 so a stale admin client can't clobber a
+// concurrent change.
+func adminPatchConfigHandler(cfgHandler *config.ConfigHandler) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body adminPatchConfigRequest
+		if err := utils.DecodeJSON(w, r, &body); err != nil {
+			utils.RespondWithJSON(w, http.StatusBadRequest, false, nil, "invalid request body")
+			return
+		}
+
+		err := cfgHandler.DoLockedAction(body.Fingerprint, func(cfg *config.Config) error {
+			return config.ApplyPatch(cfg, body.Patch)
+		})
+
+		switch {
+		case errors.Is(err, config.ErrStaleFingerprint):
+			utils.RespondWithJSON(w, http.StatusConflict, false, nil, "config fingerprint is stale, reload and retry")
+		case err != nil:
+			utils.RespondWithJSON(w, http.StatusBadRequest, false, nil, err.Error())
+		default:
+			utils.RespondWithJSON(w, http.StatusOK, true, map[string]string{"fingerprint": cfgHandler.Fingerprint()}, "")
+		}
+	}
+}
+
 func (r *routerWrapped) ClearRouteTree() {
 	r.routeTree = nil
 }
@@ -168,6 +267,8 @@ func (r *routerWrapped) registerWithChi(node *RouteNode, basePath string) {
 			r.registerMethodWithMiddleware(node, chi.Router.Post, currentPath)
 		case PUT:
 			r.registerMethodWithMiddleware(node, chi.Router.Put, currentPath)
+		case PATCH:
+			r.registerMethodWithMiddleware(node, chi.Router.Patch, currentPath)
 		case DELETE:
 			r.registerMethodWithMiddleware(node, chi.Router.Delete, currentPath)
 		}
@@ -210,6 +311,57 @@ func (n *RouteNode) Put(pattern string, f func(w http.ResponseWriter, r *http.Re
 	return n.newRouteNode(PUT, pattern, f)
 }
 
+func (n *RouteNode) Patch(pattern string, f func(w http.ResponseWriter, r *http.Request)) *RouteNode {
+	return n.newRouteNode(PATCH, pattern, f)
+}
+
 func (n *RouteNode) Delete(pattern string, f func(w http.ResponseWriter, r *http.Request)) *RouteNode {
 	return n.newRouteNode(DELETE, pattern, f)
 }
+
+// WithSummary sets the short human-readable description surfaced for this
+// route in the OpenAPI document produced by WriteOpenAPI.
+func (n *RouteNode) WithSummary(summary string) *RouteNode {
+	n.meta.Summary = summary
+	return n
+}
+
+// WithTags groups this route under the given OpenAPI tags.
+func (n *RouteNode) WithTags(tags ...string) *RouteNode {
+	n.meta.Tags = append(n.meta.Tags, tags...)
+	return n
+}
+
+// WithRequestBody documents the Go type decoded from the request body, used
+// to generate the OpenAPI request body schema.
+func (n *RouteNode) WithRequestBody(body any) *RouteNode {
+	n.meta.RequestBody = body
+	return n
+}
+
+// WithResponse documents the Go type returned in utils.APIResponse.Data for
+// the given status code.
+func (n *RouteNode) WithResponse(status int, body any) *RouteNode {
+	if n.meta.Responses == nil {
+		n.meta.Responses = make(map[int]any)
+	}
+	n.meta.Responses[status] = body
+	return n
+}
+
+// WithAuth marks this route as requiring the bearerAuth security scheme,
+// i.e. it sits behind utils.MiddlewareAPIAuth.
+func (n *RouteNode) WithAuth() *RouteNode {
+	n.meta.RequiresAuth = true
+	return n
+}
+
+// WithDeadline installs a per-request read/write deadline pair on top of
+// the global middleware.Timeout(cfg.Server.HandlerTimeout). Handlers that
+// stream (SSE, large uploads) can slide these deadlines forward per chunk
+// via utils.SetReadDeadline/utils.SetWriteDeadline. The pair's context is
+// derived from the request context, so the global HandlerTimeout still
+// cancels the request when it fires, however far the deadlines were slid.
+func (n *RouteNode) WithDeadline(read, write time.Duration) *RouteNode {
+	return n.With(utils.MiddlewareDeadline(read, write))
+}
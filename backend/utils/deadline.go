@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// deadlineTimer is a resettable one-shot deadline, modeled on the
+// deadlineTimer in gVisor's netstack gonet package: a channel is closed
+// when the deadline expires and callers select on it instead of a raw
+// timer. Set swaps in a fresh "done" channel on every call, so anyone
+// still holding a reference to the previous one via C() would otherwise
+// never learn about the slide; Changed() gives such a long-lived watcher
+// a second channel to select on that fires exactly when that happens,
+// telling it to call C() again and rebuild its select.
+type deadlineTimer struct {
+	mu      sync.Mutex
+	timer   *time.Timer
+	done    chan struct{}
+	changed chan struct{}
+}
+
+func newDeadlineTimer() *deadlineTimer {
+	return &deadlineTimer{done: make(chan struct{}), changed: make(chan struct{})}
+}
+
+// C returns the channel that closes when the current deadline expires.
+func (d *deadlineTimer) C() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.done
+}
+
+// Changed returns a channel that closes the moment Set next replaces the
+// deadline, signaling that whatever C() returned before is now stale.
+func (d *deadlineTimer) Changed() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.changed
+}
+
+// Set installs a new deadline. A zero t clears it, so C never closes
+// until the next Set. Each call allocates a fresh done channel and closes
+// the previous changed channel, waking anyone selecting on Changed() so
+// they re-read C() instead of waiting on the now-orphaned one.
+func (d *deadlineTimer) Set(t time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+
+	changed := d.changed
+	d.changed = make(chan struct{})
+	close(changed)
+
+	d.done = make(chan struct{})
+	if t.IsZero() {
+		d.timer = nil
+		return
+	}
+
+	done := d.done
+	d.timer = time.AfterFunc(time.Until(t), func() { close(done) })
+}
+
+// Deadlines is the per-request read/write deadline pair installed by
+// MiddlewareDeadline and threaded through the request context.
+type Deadlines struct {
+	Read  *deadlineTimer
+	Write *deadlineTimer
+}
+
+type deadlinesCtxKey struct{}
+
+// MiddlewareDeadline installs a cancelable read/write deadline pair in
+// the request context, mirroring RouteNode.WithDeadline. Unlike the
+// global middleware.Timeout(cfg.Server.HandlerTimeout), handlers can
+// slide these deadlines per chunk via SetReadDeadline/SetWriteDeadline;
+// the request context is canceled when either one expires. That context
+// is derived from the incoming one, so the global HandlerTimeout still
+// cancels it too: a slid deadline does not keep a request running past it.
+func MiddlewareDeadline(readTimeout, writeTimeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithCancel(r.Context())
+			defer cancel()
+
+			deadlines := &Deadlines{Read: newDeadlineTimer(), Write: newDeadlineTimer()}
+			if readTimeout > 0 {
+				deadlines.Read.Set(time.Now().Add(readTimeout))
+			}
+			if writeTimeout > 0 {
+				deadlines.Write.Set(time.Now().Add(writeTimeout))
+			}
+
+			ctx = context.WithValue(ctx, deadlinesCtxKey{}, deadlines)
+
+			// Re-reads C()/Changed() on every iteration: a slide via
+			// SetReadDeadline/SetWriteDeadline closes the relevant
+			// Changed() channel, which wakes this select so it rebuilds
+			// against the new done channel instead of the stale one it
+			// was holding.
+			go func() {
+				for {
+					// Changed() is read before C(): a Set landing between
+					// the two then closes the changed channel held here,
+					// instead of leaving the select on a stale done channel.
+					readChanged, writeChanged := deadlines.Read.Changed(), deadlines.Write.Changed()
+					select {
+					case <-deadlines.Read.C():
+						cancel()
+						return
+					case <-deadlines.Write.C():
+						cancel()
+						return
+					case <-readChanged:
+					case <-writeChanged:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// SetReadDeadline slides the current request's read deadline, e.g.
+// before blocking on the next chunk of a large upload. A no-op if the
+// route wasn't built with RouteNode.WithDeadline.
+func SetReadDeadline(ctx context.Context, t time.Time) {
+	if d, ok := ctx.Value(deadlinesCtxKey{}).(*Deadlines); ok {
+		d.Read.Set(t)
+	}
+}
+
+// SetWriteDeadline slides the current request's write deadline, e.g.
+// before writing the next SSE event. A no-op if the route wasn't built
+// with RouteNode.WithDeadline.
+func SetWriteDeadline(ctx context.Context, t time.Time) {
+	if d, ok := ctx.Value(deadlinesCtxKey{}).(*Deadlines); ok {
+		d.Write.Set(t)
+	}
+}
+
+// readDeadlineDone returns the channel that closes when the request's
+// read deadline expires, or nil if none was installed; DecodeJSON
+// selects on it so a stalled request body read is interrupted instead of
+// blocking past the deadline.
+func readDeadlineDone(ctx context.Context) <-chan struct{} {
+	if d, ok := ctx.Value(deadlinesCtxKey{}).(*Deadlines); ok {
+		return d.Read.C()
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ Package utils ...
 package utils
 
 import (
+	"context"
 	"crypto/subtle"
 	"encoding/json"
 	"log"
@@ -30,15 +31,30 @@ func RespondWithJSON(w http.ResponseWriter, status int, success bool, data any,
 	json.NewEncoder(w).Encode(response)
 }
 
+// DecodeJSON decodes the request body into req. If the route was built
+// with RouteNode.WithDeadline, decoding is interrupted as soon as the
+// read deadline fires instead of blocking on a slow/stalled client past
+// that point. On a non-nil error req must be treated as unusable: the
+// decode goroutine may still be running (and, on the deadline path,
+// still writing into req) after this function has returned.
 func DecodeJSON(w http.ResponseWriter, r *http.Request, req any) error {
 	r.Body = http.MaxBytesReader(w, r.Body, 64<<10)
 	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
-	if err := dec.Decode(req); err != nil {
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- dec.Decode(req) }()
+
+	select {
+	case err := <-errCh:
 		return err
+	case <-readDeadlineDone(r.Context()):
+		// Closing the body unblocks the goroutine's pending Read so it
+		// exits instead of leaking until the client eventually closes
+		// the connection.
+		r.Body.Close()
+		return context.DeadlineExceeded
 	}
-
-	return nil
 }
 
 func MiddlewareAPIAuth(configAPIKey string) func(next http.Handler) http.Handler {
@@ -47,13 +63,13 @@ func MiddlewareAPIAuth(configAPIKey string) func(next http.Handler) http.Handler
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
 				RespondWithJSON(w, http.StatusUnauthorized, false, nil, "Missing API Key")
-				log.Printf("Attempt to send request with a missing API Key from: %s", r.RemoteAddr)
+				Logger.Warn("missing API key", "trace_id", TraceID(r), "remote_addr", r.RemoteAddr)
 				return
 			}
 
 			apiKeyPrefix := "Bearer "
 			if !strings.HasPrefix(authHeader, apiKeyPrefix) {
-				log.Printf("Attempt to send request with invalid Authorization format from: %s", r.RemoteAddr)
+				Logger.Warn("invalid Authorization format", "trace_id", TraceID(r), "remote_addr", r.RemoteAddr)
 				RespondWithJSON(w, http.StatusUnauthorized, false, nil, "Invalid Authorization format.")
 				return
 			}
@@ -61,7 +77,7 @@ func MiddlewareAPIAuth(configAPIKey string) func(next http.Handler) http.Handler
 			headerAPIKey := strings.TrimPrefix(authHeader, apiKeyPrefix)
 			if subtle.ConstantTimeCompare([]byte(headerAPIKey), []byte(configAPIKey)) != 1 {
 				RespondWithJSON(w, http.StatusUnauthorized, false, nil, "Invalid API Key")
-				log.Printf("Attempt to send request with an invalid API Key from: %s", r.RemoteAddr)
+				Logger.Warn("invalid API key", "trace_id", TraceID(r), "remote_addr", r.RemoteAddr)
 				return
 			}
 
@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/httprate"
+	"github.com/go-redis/redis/v8"
+
+	"backend/metrics"
+)
+
+// RateLimitConfig configures a single rate-limit middleware instance,
+// whether it's the global per-IP limiter installed by router.New or a
+// stricter per-route limiter attached via RouteNode.With(MiddlewareRateLimit(...)).
+type RateLimitConfig struct {
+	Backend       string // "memory" or "redis"
+	RedisURL      string
+	Limit         int
+	ClearInterval time.Duration
+}
+
+// MiddlewareRateLimit builds a rate-limit middleware from whatever
+// getCfg returns. When the backend is "redis" it counts requests in
+// Redis, keyed by IP and chi's matched route pattern, so the limit is
+// shared across every stund instance behind a load balancer, and getCfg
+// is re-read on every request so a config hot reload changes the limit
+// immediately. The route pattern is only known once chi has routed the
+// request, so a limiter that runs before routing (the global one in
+// router.New) keys all of an IP's routes under one "__unmatched__" key
+// and labels its rejections the same way; one attached via RouteNode.With
+// sees the route's pattern. If Redis is unset or unreachable at
+// construction it falls back to the in-memory httprate limiter, and a
+// Redis error on a request sends that request through such a limiter;
+// its limit/window are fixed at construction time since httprate itself
+// doesn't support changing them live. Call with a getter
+// that always returns the same value (e.g. `func() RateLimitConfig {
+// return cfg }`) to attach a static per-route limit via RouteNode.With.
+func MiddlewareRateLimit(getCfg func() RateLimitConfig) func(http.Handler) http.Handler {
+	cfg := getCfg()
+	if cfg.Backend == "redis" && cfg.RedisURL != "" {
+		mw, err := newRedisRateLimiter(getCfg)
+		if err == nil {
+			return mw
+		}
+		log.Printf("Redis rate limiter unavailable, falling back to in-memory limiter: %v", err)
+	}
+
+	limiter := httprate.LimitByRealIP(cfg.Limit, cfg.ClearInterval)
+	return func(next http.Handler) http.Handler {
+		limited := limiter(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			limited.ServeHTTP(rec, r)
+			if rec.status == http.StatusTooManyRequests {
+				metrics.RateLimitRejections.WithLabelValues(metrics.RoutePattern(r)).Inc()
+			}
+		})
+	}
+}
+
+// statusRecorder captures the status code written by a wrapped handler so
+// callers can observe it without the handler cooperating.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// rateLimitScript atomically increments the counter for a window and sets
+// its expiry the first time it's created, so INCR and EXPIRE never race
+// across instances.
+var rateLimitScript = redis.NewScript(`
+local current = redis.call("INCR", KEYS[1])
+if tonumber(current) == 1 then
+	redis.call("EXPIRE", KEYS[1], ARGV[1])
+end
+return current
+`)
+
+// realIP strips the ephemeral port chi's RealIP middleware leaves in place
+// for non-proxied requests (it only rewrites RemoteAddr when an
+// X-Forwarded-For/X-Real-IP header is present), so the redis limiter keys
+// on the same address the in-memory httprate.LimitByRealIP path uses.
+func realIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func newRedisRateLimiter(getCfg func() RateLimitConfig) (func(http.Handler) http.Handler, error) {
+	cfg := getCfg()
+
+	opts, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse REDIS_URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+
+	pingCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := client.Ping(pingCtx).Err(); err != nil {
+		return nil, fmt.Errorf("connect to redis: %w", err)
+	}
+
+	return func(next http.Handler) http.Handler {
+		fallback := httprate.LimitByRealIP(cfg.Limit, cfg.ClearInterval)(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cfg := getCfg()
+			key := fmt.Sprintf("ratelimit:%s:%s", realIP(r), metrics.RoutePattern(r))
+
+			count, err := rateLimitScript.Run(r.Context(), client, []string{key}, int(cfg.ClearInterval.Seconds())).Int()
+			if err != nil {
+				log.Printf("Redis rate limiter error, falling back to in-memory limiter: %v", err)
+				rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+				fallback.ServeHTTP(rec, r)
+				if rec.status == http.StatusTooManyRequests {
+					metrics.RateLimitRejections.WithLabelValues(metrics.RoutePattern(r)).Inc()
+				}
+				return
+			}
+
+			if count > cfg.Limit {
+				metrics.RateLimitRejections.WithLabelValues(metrics.RoutePattern(r)).Inc()
+				RespondWithJSON(w, http.StatusTooManyRequests, false, nil, "Rate limit exceeded")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}